@@ -0,0 +1,337 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/golang/dep/internal/gps"
+	"github.com/golang/dep/internal/lockedfile"
+	"github.com/golang/dep/internal/modconv"
+	"github.com/pkg/errors"
+)
+
+// projectLocks and projectLocksMu back projectLock below.
+var (
+	projectLocksMu sync.Mutex
+	projectLocks   = map[string]*lockedfile.Mutex{}
+)
+
+// projectLock returns the lockedfile.Mutex shared by every caller in this
+// process locking path, creating it on first use. A fresh literal per call
+// would give each caller its own embedded sync.Mutex, so two in-process
+// goroutines locking the same Gopkg.lock would never contend on a shared
+// Go-level mutex - only on the OS-level flock - and the race detector
+// wouldn't see a happens-before edge between them.
+func projectLock(path string) *lockedfile.Mutex {
+	projectLocksMu.Lock()
+	defer projectLocksMu.Unlock()
+
+	m, ok := projectLocks[path]
+	if !ok {
+		m = &lockedfile.Mutex{Path: path}
+		projectLocks[path] = m
+	}
+	return m
+}
+
+// Ctx defines the supporting context of the tool, consisting of the GOPATH(s),
+// the working directory within one of them, and the loggers to use for
+// output.
+type Ctx struct {
+	GOPATH     string   // Selected GOPATH (the one containing WorkingDir)
+	GOPATHs    []string // Other GOPATHs
+	WorkingDir string
+	Out, Err   *log.Logger
+	Verbose    bool
+
+	// ModuleRoot, if set, is the import path the user has declared for their
+	// project (e.g. via a `module = "..."` line in Gopkg.toml, or a
+	// `-module` flag). When set, LoadProject finds the project by walking up
+	// from WorkingDir for a manifest, without ever consulting a GOPATH.
+	ModuleRoot string
+
+	// manifestDir is the directory LoadProject found the manifest in, set as
+	// a side effect of loading; it anchors SplitAbsoluteProjectRoot's
+	// relative-path math in ModuleRoot mode.
+	manifestDir string
+}
+
+// SetPaths sets the WorkingDir and GOPATHs fields on ctx, selecting the
+// GOPATH that contains wd as the active GOPATH.
+func (c *Ctx) SetPaths(wd string, gopaths ...string) error {
+	if wd == "" {
+		return errors.New("cannot have empty working directory")
+	}
+	c.WorkingDir = wd
+	c.GOPATHs = gopaths
+
+	gopath, err := c.detectGOPATH(wd)
+	if err != nil {
+		return err
+	}
+	c.GOPATH = gopath
+
+	return nil
+}
+
+// detectGOPATH finds the GOPATH containing the given path. If the path is
+// not within any GOPATH, an error is returned.
+func (c *Ctx) detectGOPATH(path string) (string, error) {
+	for _, gp := range c.GOPATHs {
+		isPrefix, err := isCaseSensitivePrefixOf(gp, path)
+		if err != nil {
+			return "", err
+		}
+		if isPrefix {
+			return gp, nil
+		}
+	}
+	return "", errors.Errorf("could not determine GOPATH containing %q", path)
+}
+
+// isCaseSensitivePrefixOf reports whether path is lexically within base,
+// using filepath.Rel: the comparison ignores case on Windows, where GOPATH
+// is frequently on a case-insensitive filesystem, and is case-sensitive on
+// other platforms.
+func isCaseSensitivePrefixOf(base, path string) (bool, error) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return false, nil
+	}
+	return !strings.HasPrefix(rel, ".."), nil
+}
+
+// SplitAbsoluteProjectRoot takes an absolute path and compares it against the
+// detected GOPATH to determine what portion of the input path should be used
+// as the import path. In ModuleRoot mode, it instead compares path against
+// the discovered manifest directory and rewrites it in terms of the declared
+// module path, without requiring a GOPATH at all.
+func (c *Ctx) SplitAbsoluteProjectRoot(path string) (string, error) {
+	if c.ModuleRoot != "" {
+		return c.splitModuleProjectRoot(path)
+	}
+
+	srcprefix := filepath.Join(c.GOPATH, "src") + string(filepath.Separator)
+	if strings.HasPrefix(path, srcprefix) {
+		p := strings.TrimPrefix(path, srcprefix)
+		if p == "" {
+			return "", errors.Errorf("%s is the GOPATH/src directory, not a project", path)
+		}
+		return filepath.ToSlash(p), nil
+	}
+	return "", errors.Errorf("%s not in GOPATH/src", path)
+}
+
+// splitModuleProjectRoot rewrites path as an import path rooted at
+// c.ModuleRoot, provided path is the manifest directory itself or one of its
+// subdirectories.
+func (c *Ctx) splitModuleProjectRoot(path string) (string, error) {
+	root := c.manifestDir
+	if root == "" {
+		root = c.WorkingDir
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("%s is not within the module root %s", path, root)
+	}
+	if rel == "." {
+		return c.ModuleRoot, nil
+	}
+	return joinModuleSubpath(c.ModuleRoot, rel), nil
+}
+
+// joinModuleSubpath joins a module path with a filesystem-relative suffix,
+// always using forward slashes regardless of platform.
+func joinModuleSubpath(moduleRoot, rel string) string {
+	return moduleRoot + "/" + filepath.ToSlash(rel)
+}
+
+// absoluteProjectRoot determines the absolute path to the project root
+// (GOPATH + "src" + import path) and validates that it is an existing
+// directory.
+func (c *Ctx) absoluteProjectRoot(importPath string) (string, error) {
+	posspath := filepath.Join(c.GOPATH, "src", importPath)
+	dirOK, err := isDir(posspath)
+	if err != nil {
+		return "", errors.Wrapf(err, "checking project root %s", posspath)
+	}
+	if !dirOK {
+		return "", errors.Errorf("%s does not exist", posspath)
+	}
+	return posspath, nil
+}
+
+func isDir(path string) (bool, error) {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return fi.IsDir(), nil
+}
+
+// LoadProject starts from the working directory and searches up the
+// directory tree for a project root, indicated by the presence of a manifest
+// file, loading the manifest and any lock file it finds along the way.
+func (c *Ctx) LoadProject() (*Project, error) {
+	root, err := findProjectRoot(c.WorkingDir)
+	if err != nil {
+		return nil, err
+	}
+	c.manifestDir = root
+
+	// Hold a shared lock on Gopkg.lock for the duration of the load, so that
+	// a concurrent `dep ensure` can't rewrite it out from under us mid-read.
+	// There's nothing to lock against if the project has no lock file yet.
+	unlock, err := projectLock(filepath.Join(root, LockName)).RLock()
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, errors.Wrap(err, "locking project for read")
+	default:
+		defer unlock()
+	}
+
+	p := new(Project)
+	p.AbsRoot = root
+	p.ResolvedAbsRoot = root
+
+	mf, err := os.Open(filepath.Join(root, ManifestName))
+	if err != nil {
+		// No Gopkg.toml; see if a go.mod can stand in for it before giving up.
+		gomodManifest, gomodLock, gerr := importGoMod(root)
+		if gerr != nil {
+			return nil, gerr
+		}
+		if gomodManifest == nil {
+			return nil, errors.Errorf("no %s found in %q", ManifestName, root)
+		}
+		p.Manifest, p.Lock = gomodManifest, gomodLock
+
+		importroot, err := c.SplitAbsoluteProjectRoot(root)
+		if err != nil {
+			return nil, errors.Wrap(err, "split absolute project root")
+		}
+		p.ImportRoot = gps.ProjectRoot(importroot)
+		return p, nil
+	}
+	defer mf.Close()
+
+	p.Manifest, err = readManifest(mf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while parsing %s", filepath.Join(root, ManifestName))
+	}
+
+	// A `module = "..."` line in Gopkg.toml declares the project's import
+	// path directly, the same way passing Ctx.ModuleRoot does; let it
+	// switch LoadProject into ModuleRoot mode if the caller didn't already.
+	if c.ModuleRoot == "" && p.Manifest.Module != "" {
+		c.ModuleRoot = p.Manifest.Module
+	}
+
+	importroot, err := c.SplitAbsoluteProjectRoot(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "split absolute project root")
+	}
+	p.ImportRoot = gps.ProjectRoot(importroot)
+
+	lf, err := os.Open(filepath.Join(root, LockName))
+	if err == nil {
+		defer lf.Close()
+		p.Lock, err = readLock(lf)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error while parsing %s", filepath.Join(root, LockName))
+		}
+	}
+
+	return p, nil
+}
+
+// WithProjectLock runs fn while holding an exclusive lock on the project's
+// Gopkg.lock, so that a concurrent LoadProject (in this process or another)
+// can't observe a partial write. Callers doing `dep ensure`-style writes to
+// the manifest or lock should wrap them in this.
+func (c *Ctx) WithProjectLock(fn func() error) error {
+	root := c.manifestDir
+	if root == "" {
+		root = c.WorkingDir
+	}
+	unlock, err := projectLock(filepath.Join(root, LockName)).Lock()
+	if err != nil {
+		return errors.Wrap(err, "locking project for write")
+	}
+	defer unlock()
+
+	return fn()
+}
+
+// findProjectRoot searches from dir upward for a directory containing a
+// manifest file.
+func findProjectRoot(dir string) (string, error) {
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, ManifestName)); err == nil && !fi.IsDir() {
+			return dir, nil
+		}
+		if fi, err := os.Stat(filepath.Join(dir, modconv.GoModName)); err == nil && !fi.IsDir() {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.Errorf("no %s found in %q or any parent directory", ManifestName, dir)
+		}
+		dir = parent
+	}
+}
+
+// VersionInWorkspace checks that the given project root exists as a
+// directory under GOPATH/src. It does not read the version of the checkout
+// there: it always returns an empty gps.Revision and an error.
+func (c *Ctx) VersionInWorkspace(root gps.ProjectRoot) (gps.Version, error) {
+	pr, err := c.absoluteProjectRoot(string(root))
+	if err != nil {
+		return nil, errors.Wrapf(err, "determine project root for %s", root)
+	}
+
+	return gps.Revision(""), errors.Errorf("could not determine version for %s in %s: %v", root, pr, err)
+}
+
+// DetectProjectGOPATH determines what the project's GOPATH should be. If
+// the resolved AbsRoot and ResolvedAbsRoot are not the same, then the
+// resolved AbsRoot is checked for being a symlink, or being within a symlinked
+// directory, relative to the GOPATH.
+func (c *Ctx) DetectProjectGOPATH(p *Project) (string, error) {
+	if c.ModuleRoot != "" {
+		// No GOPATH assumption is made in ModuleRoot mode.
+		return "", nil
+	}
+
+	if p.AbsRoot == "" || p.ResolvedAbsRoot == "" {
+		return "", errors.New("project AbsRoot and ResolvedAbsRoot must be set")
+	}
+
+	if p.AbsRoot == p.ResolvedAbsRoot {
+		return c.detectGOPATH(p.AbsRoot)
+	}
+
+	// AbsRoot and ResolvedAbsRoot differ, so AbsRoot must be reached through
+	// a symlink somewhere above the project root. If AbsRoot is already
+	// within a configured GOPATH, there's no symlink-out-of-GOPATH story
+	// that explains the discrepancy, so refuse to guess at a GOPATH rather
+	// than silently picking the wrong one.
+	if gopath, err := c.detectGOPATH(p.AbsRoot); err == nil {
+		return "", errors.Errorf("%s is within GOPATH %s, but resolves to a different directory %s", p.AbsRoot, gopath, p.ResolvedAbsRoot)
+	}
+
+	return c.detectGOPATH(p.ResolvedAbsRoot)
+}
@@ -149,34 +149,35 @@ func TestVersionInWorkspace(t *testing.T) {
 }
 
 func TestLoadProject(t *testing.T) {
-	h := test.NewHelper(t)
-	defer h.Cleanup()
-
-	h.TempDir(filepath.Join("src", "test1", "sub"))
-	h.TempFile(filepath.Join("src", "test1", ManifestName), "")
-	h.TempFile(filepath.Join("src", "test1", LockName), `memo = "cdafe8641b28cd16fe025df278b0a49b9416859345d8b6ba0ace0272b74925ee"`)
-	h.TempDir(filepath.Join("src", "test2", "sub"))
-	h.TempFile(filepath.Join("src", "test2", ManifestName), "")
-
 	var testcases = []struct {
-		name string
-		lock bool
-		wd   string
+		name    string
+		fixture string
+		lock    bool
+		sub     bool
 	}{
-		{"direct", true, filepath.Join("src", "test1")},
-		{"ascending", true, filepath.Join("src", "test1", "sub")},
-		{"without lock", false, filepath.Join("src", "test2")},
-		{"ascending without lock", false, filepath.Join("src", "test2", "sub")},
+		{"direct", "loadproject_with_lock.txtar", true, false},
+		{"ascending", "loadproject_with_lock.txtar", true, true},
+		{"without lock", "loadproject_without_lock.txtar", false, false},
+		{"ascending without lock", "loadproject_without_lock.txtar", false, true},
 	}
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
+			h := test.NewHelper(t)
+			defer h.Cleanup()
+
+			root := h.ExtractTxtar(filepath.Join("testdata", tc.fixture))
+			wd := root
+			if tc.sub {
+				wd = filepath.Join(root, "sub")
+			}
+
 			ctx := &Ctx{
 				Out: discardLogger,
 				Err: discardLogger,
 			}
 
-			err := ctx.SetPaths(h.Path(tc.wd), h.Path("."))
+			err := ctx.SetPaths(wd, filepath.Dir(filepath.Dir(root)))
 			if err != nil {
 				t.Fatalf("%+v", err)
 			}
@@ -184,13 +185,13 @@ func TestLoadProject(t *testing.T) {
 			p, err := ctx.LoadProject()
 			switch {
 			case err != nil:
-				t.Fatalf("%s: LoadProject failed: %+v", tc.wd, err)
+				t.Fatalf("%s: LoadProject failed: %+v", wd, err)
 			case p.Manifest == nil:
-				t.Fatalf("%s: Manifest file didn't load", tc.wd)
+				t.Fatalf("%s: Manifest file didn't load", wd)
 			case tc.lock && p.Lock == nil:
-				t.Fatalf("%s: Lock file didn't load", tc.wd)
+				t.Fatalf("%s: Lock file didn't load", wd)
 			case !tc.lock && p.Lock != nil:
-				t.Fatalf("%s: Non-existent Lock file loaded", tc.wd)
+				t.Fatalf("%s: Non-existent Lock file loaded", wd)
 			}
 		})
 	}
@@ -228,28 +229,17 @@ func TestLoadProjectManifestParseError(t *testing.T) {
 	tg := test.NewHelper(t)
 	defer tg.Cleanup()
 
-	tg.TempDir("src")
-	tg.TempDir("src/test1")
-	tg.TempFile(filepath.Join("src/test1", ManifestName), `[[constraint]]`)
-	tg.TempFile(filepath.Join("src/test1", LockName), `memo = "cdafe8641b28cd16fe025df278b0a49b9416859345d8b6ba0ace0272b74925ee"\n\n[[projects]]`)
-	tg.Setenv("GOPATH", tg.Path("."))
-
-	path := filepath.Join("src", "test1")
-	tg.Cd(tg.Path(path))
-
-	wd, err := os.Getwd()
-	if err != nil {
-		t.Fatal("failed to get working directory", err)
-	}
+	root := tg.ExtractTxtar(filepath.Join("testdata", "loadproject_manifest_parse_error.txtar"))
+	tg.Cd(root)
 
 	ctx := &Ctx{
-		GOPATH:     tg.Path("."),
-		WorkingDir: wd,
+		GOPATH:     filepath.Dir(filepath.Dir(root)),
+		WorkingDir: root,
 		Out:        discardLogger,
 		Err:        discardLogger,
 	}
 
-	_, err = ctx.LoadProject()
+	_, err := ctx.LoadProject()
 	if err == nil {
 		t.Fatal("should have returned 'Manifest Syntax' error")
 	}
@@ -259,28 +249,17 @@ func TestLoadProjectLockParseError(t *testing.T) {
 	tg := test.NewHelper(t)
 	defer tg.Cleanup()
 
-	tg.TempDir("src")
-	tg.TempDir("src/test1")
-	tg.TempFile(filepath.Join("src/test1", ManifestName), `[[constraint]]`)
-	tg.TempFile(filepath.Join("src/test1", LockName), `memo = "cdafe8641b28cd16fe025df278b0a49b9416859345d8b6ba0ace0272b74925ee"\n\n[[projects]]`)
-	tg.Setenv("GOPATH", tg.Path("."))
-
-	path := filepath.Join("src", "test1")
-	tg.Cd(tg.Path(path))
-
-	wd, err := os.Getwd()
-	if err != nil {
-		t.Fatal("failed to get working directory", err)
-	}
+	root := tg.ExtractTxtar(filepath.Join("testdata", "loadproject_lock_parse_error.txtar"))
+	tg.Cd(root)
 
 	ctx := &Ctx{
-		GOPATH:     tg.Path("."),
-		WorkingDir: wd,
+		GOPATH:     filepath.Dir(filepath.Dir(root)),
+		WorkingDir: root,
 		Out:        discardLogger,
 		Err:        discardLogger,
 	}
 
-	_, err = ctx.LoadProject()
+	_, err := ctx.LoadProject()
 	if err == nil {
 		t.Fatal("should have returned 'Lock Syntax' error")
 	}
@@ -290,14 +269,10 @@ func TestLoadProjectNoSrcDir(t *testing.T) {
 	tg := test.NewHelper(t)
 	defer tg.Cleanup()
 
-	tg.TempDir("test1")
-	tg.TempFile(filepath.Join("test1", ManifestName), `[[constraint]]`)
-	tg.TempFile(filepath.Join("test1", LockName), `memo = "cdafe8641b28cd16fe025df278b0a49b9416859345d8b6ba0ace0272b74925ee"\n\n[[projects]]`)
-	tg.Setenv("GOPATH", tg.Path("."))
+	root := tg.ExtractTxtar(filepath.Join("testdata", "loadproject_no_src_dir.txtar"))
+	tg.Cd(root)
 
-	ctx := &Ctx{GOPATH: tg.Path(".")}
-	path := filepath.Join("test1")
-	tg.Cd(tg.Path(path))
+	ctx := &Ctx{GOPATH: filepath.Dir(root)}
 
 	f, _ := os.OpenFile(filepath.Join(ctx.GOPATH, "src", "test1", LockName), os.O_WRONLY, os.ModePerm)
 	defer f.Close()
@@ -451,6 +426,62 @@ func TestDetectProjectGOPATH(t *testing.T) {
 	}
 }
 
+func TestLoadProjectModuleRoot(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	h.TempDir(filepath.Join("proj", "sub"))
+	h.TempFile(filepath.Join("proj", ManifestName), `module = "example.com/proj"`)
+
+	depCtx := &Ctx{
+		ModuleRoot: "example.com/proj",
+		Out:        discardLogger,
+		Err:        discardLogger,
+	}
+	depCtx.WorkingDir = h.Path(filepath.Join("proj", "sub"))
+
+	p, err := depCtx.LoadProject()
+	if err != nil {
+		t.Fatalf("LoadProject failed: %+v", err)
+	}
+	if string(p.ImportRoot) != "example.com/proj" {
+		t.Fatalf("expected import root example.com/proj, got %s", p.ImportRoot)
+	}
+
+	gopath, err := depCtx.DetectProjectGOPATH(p)
+	if err != nil {
+		t.Fatalf("DetectProjectGOPATH failed: %+v", err)
+	}
+	if gopath != "" {
+		t.Fatalf("expected no GOPATH in ModuleRoot mode, got %s", gopath)
+	}
+}
+
+func TestLoadProjectModuleRootFromManifest(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	h.TempDir(filepath.Join("proj", "sub"))
+	h.TempFile(filepath.Join("proj", ManifestName), `module = "example.com/proj"`)
+
+	depCtx := &Ctx{
+		Out:        discardLogger,
+		Err:        discardLogger,
+		WorkingDir: h.Path(filepath.Join("proj", "sub")),
+	}
+
+	p, err := depCtx.LoadProject()
+	if err != nil {
+		t.Fatalf("LoadProject failed: %+v", err)
+	}
+	if string(p.ImportRoot) != "example.com/proj" {
+		t.Fatalf("expected import root example.com/proj, got %s", p.ImportRoot)
+	}
+	if depCtx.ModuleRoot != "example.com/proj" {
+		t.Fatalf("expected the manifest's module line to populate Ctx.ModuleRoot, got %q", depCtx.ModuleRoot)
+	}
+}
+
 func TestDetectGOPATH(t *testing.T) {
 	th := test.NewHelper(t)
 	defer th.Cleanup()
@@ -484,3 +515,12 @@ func TestDetectGOPATH(t *testing.T) {
 		}
 	}
 }
+
+func TestProjectLockShared(t *testing.T) {
+	if got, want := projectLock("/tmp/a/Gopkg.lock"), projectLock("/tmp/a/Gopkg.lock"); got != want {
+		t.Error("projectLock returned a different *lockedfile.Mutex for the same path")
+	}
+	if got, other := projectLock("/tmp/a/Gopkg.lock"), projectLock("/tmp/b/Gopkg.lock"); got == other {
+		t.Error("projectLock returned the same *lockedfile.Mutex for different paths")
+	}
+}
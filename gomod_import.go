@@ -0,0 +1,95 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golang/dep/internal/gps"
+	"github.com/golang/dep/internal/modconv"
+	"github.com/pkg/errors"
+)
+
+// importGoMod reads the go.mod (and, if present, go.sum) at root and
+// synthesizes the *Manifest and *Lock dep would have produced from an
+// equivalent Gopkg.toml/Gopkg.lock pair. It returns (nil, nil, nil) if root
+// does not contain a go.mod, so callers can fall back to looking for other
+// manifest formats.
+func importGoMod(root string) (*Manifest, *Lock, error) {
+	if _, err := os.Stat(filepath.Join(root, modconv.GoModName)); os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+
+	gm, err := modconv.ParseGoMod(root)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error while parsing %s", filepath.Join(root, modconv.GoModName))
+	}
+
+	excluded := make(map[string]bool, len(gm.Excludes))
+	for _, ex := range gm.Excludes {
+		excluded[ex.Path+"@"+ex.Version] = true
+	}
+
+	m := &Manifest{}
+	for _, req := range gm.Requires {
+		if excluded[req.Path+"@"+req.Version] {
+			m.Ignored = append(m.Ignored, req.Path)
+			continue
+		}
+		m.Constraints = append(m.Constraints, modconv.Constraint(req))
+	}
+	for _, rep := range gm.Replaces {
+		pc := gps.ProjectConstraint{Ident: gps.ProjectRoot(rep.Old.Path)}
+		if filepath.IsAbs(rep.New.Path) || rep.New.Path[0] == '.' {
+			// Local path replace: pin the source, not a version.
+			pc.Source = rep.New.Path
+		} else {
+			pc.Source = rep.New.Path
+			pc.Constraint = modconv.Constraint(rep.New).Constraint
+		}
+		m.Ovr = append(m.Ovr, pc)
+	}
+
+	l := &Lock{}
+	if sums, err := modconv.ParseGoSum(root); err == nil {
+		l.SolveMeta.InputsDigest = goSumDigest(sums)
+	}
+	for _, req := range gm.Requires {
+		if excluded[req.Path+"@"+req.Version] {
+			// Ignored in the manifest above; don't also pin it in the lock.
+			continue
+		}
+		lp := LockedProject{Ident: gps.ProjectRoot(req.Path)}
+		lp.Version = modconv.Constraint(req).Constraint.(gps.Version)
+		l.P = append(l.P, lp)
+	}
+
+	return m, l, nil
+}
+
+// goSumDigest deterministically hashes a go.sum's module@version -> h1 hash
+// pairs, so that re-importing an unchanged go.sum always yields the same
+// InputsDigest and a tampered hash (not just a tampered module/version)
+// changes it. Map iteration order is randomized, so the pairs are sorted by
+// key before hashing.
+func goSumDigest(sums map[string]string) []byte {
+	keys := make([]string, 0, len(sums))
+	for key := range sums {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(sums[key]))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/dep/internal/gps"
+	"github.com/golang/dep/internal/test"
+)
+
+func TestImportGoMod(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	h.TempFile("go.mod", `module example.com/foo
+
+require example.com/bar v1.2.3
+require example.com/baz v2.0.0
+
+exclude example.com/baz v2.0.0
+`)
+
+	m, l, err := importGoMod(h.Path("."))
+	if err != nil {
+		t.Fatalf("importGoMod failed: %+v", err)
+	}
+
+	if len(m.Constraints) != 1 || m.Constraints[0].Ident != gps.ProjectRoot("example.com/bar") {
+		t.Errorf("expected only the non-excluded requirement as a constraint, got %+v", m.Constraints)
+	}
+	if len(m.Ignored) != 1 || m.Ignored[0] != "example.com/baz" {
+		t.Errorf("expected excluded requirement to be ignored, got %+v", m.Ignored)
+	}
+
+	for _, lp := range l.P {
+		if lp.Ident == gps.ProjectRoot("example.com/baz") {
+			t.Errorf("excluded project example.com/baz must not also be pinned in the lock: %+v", l.P)
+		}
+	}
+	if len(l.P) != 1 || l.P[0].Ident != gps.ProjectRoot("example.com/bar") {
+		t.Errorf("expected only the non-excluded requirement in the lock, got %+v", l.P)
+	}
+}
+
+func TestGoSumDigestDeterministicAndHashSensitive(t *testing.T) {
+	sums := map[string]string{
+		"example.com/bar@v1.2.3": "h1:abc=",
+		"example.com/baz@v2.0.0": "h1:def=",
+	}
+
+	a := goSumDigest(sums)
+	b := goSumDigest(sums)
+	if !bytes.Equal(a, b) {
+		t.Errorf("goSumDigest is not deterministic across map iterations: %x != %x", a, b)
+	}
+
+	tampered := map[string]string{
+		"example.com/bar@v1.2.3": "h1:tampered=",
+		"example.com/baz@v2.0.0": "h1:def=",
+	}
+	if c := goSumDigest(tampered); bytes.Equal(a, c) {
+		t.Error("goSumDigest did not change when a hash value was tampered with")
+	}
+}
+
+func TestImportGoModNoFile(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	m, l, err := importGoMod(h.Path("."))
+	if err != nil {
+		t.Fatalf("importGoMod failed: %+v", err)
+	}
+	if m != nil || l != nil {
+		t.Fatalf("expected (nil, nil, nil) when no go.mod is present, got (%+v, %+v, nil)", m, l)
+	}
+}
+
+func TestLoadProjectGoModFallback(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	h.TempFile("go.mod", `module example.com/foo
+
+require example.com/bar v1.2.3
+`)
+
+	depCtx := &Ctx{
+		Out:        discardLogger,
+		Err:        discardLogger,
+		WorkingDir: h.Path("."),
+		// go.mod projects commonly live outside any GOPATH; ModuleRoot is how
+		// a caller (e.g. a -module flag) tells LoadProject the import path to
+		// use in that case.
+		ModuleRoot: "example.com/foo",
+	}
+
+	p, err := depCtx.LoadProject()
+	if err != nil {
+		t.Fatalf("LoadProject failed: %+v", err)
+	}
+	if string(p.ImportRoot) != "example.com/foo" {
+		t.Fatalf("expected import root example.com/foo, got %s", p.ImportRoot)
+	}
+	if len(p.Manifest.Constraints) != 1 || p.Manifest.Constraints[0].Ident != gps.ProjectRoot("example.com/bar") {
+		t.Fatalf("expected go.mod require to become a constraint, got %+v", p.Manifest.Constraints)
+	}
+}
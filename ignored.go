@@ -0,0 +1,113 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import "strings"
+
+// ignorePattern is a single compiled gitignore-style pattern from the
+// manifest's ignored list.
+type ignorePattern struct {
+	segments []string // pattern split on "/"; "**" matches zero or more segments
+	negate   bool     // pattern began with "!"
+}
+
+// compileIgnored compiles the manifest's raw ignored entries into matchers,
+// preserving their original order so that later negations can rescue
+// packages matched by an earlier pattern.
+func compileIgnored(patterns []string) []ignorePattern {
+	compiled := make([]ignorePattern, 0, len(patterns))
+	for _, p := range patterns {
+		negate := false
+		if strings.HasPrefix(p, "!") {
+			negate = true
+			p = p[1:]
+		}
+		compiled = append(compiled, ignorePattern{
+			segments: strings.Split(p, "/"),
+			negate:   negate,
+		})
+	}
+	return compiled
+}
+
+func (pat ignorePattern) match(path []string) bool {
+	return matchSegments(pat.segments, path)
+}
+
+// matchSegments reports whether path matches pattern, where "**" in pattern
+// matches zero or more whole path components.
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			// A trailing "**" matches the contents of a directory, not the
+			// directory itself, so it needs at least one more component.
+			return len(path) > 0
+		}
+		if matchSegments(pattern[1:], path) {
+			return true
+		}
+		if len(path) == 0 {
+			return false
+		}
+		return matchSegments(pattern, path[1:])
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	if !matchSegment(pattern[0], path[0]) {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
+
+// matchSegment matches a single path component against a single pattern
+// component, which may contain "*" wildcards.
+func matchSegment(pat, seg string) bool {
+	if pat == "*" {
+		return true
+	}
+	if !strings.Contains(pat, "*") {
+		return pat == seg
+	}
+
+	parts := strings.Split(pat, "*")
+	if !strings.HasPrefix(seg, parts[0]) {
+		return false
+	}
+	seg = seg[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(seg, part)
+		if i < 0 {
+			return false
+		}
+		seg = seg[i+len(part):]
+	}
+	return strings.HasSuffix(seg, parts[len(parts)-1])
+}
+
+// IgnoredPackages returns a matcher for the project's ignored import paths.
+// Patterns are gitignore-style: "**" matches zero or more path components, a
+// leading "!" negates a pattern (rescuing a path matched by an earlier
+// pattern), and later patterns take precedence over earlier ones. The
+// returned func is safe for concurrent use by gps during solving.
+func (p *Project) IgnoredPackages() func(importPath string) bool {
+	compiled := compileIgnored(p.Manifest.Ignored)
+
+	return func(importPath string) bool {
+		path := strings.Split(importPath, "/")
+		ignored := false
+		for _, pat := range compiled {
+			if pat.match(path) {
+				ignored = !pat.negate
+			}
+		}
+		return ignored
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import "testing"
+
+func TestProjectIgnoredPackages(t *testing.T) {
+	p := &Project{
+		Manifest: &Manifest{
+			Ignored: []string{
+				"example.com/foo/**",
+				"!example.com/foo/keepme",
+				"**/internal/testdata",
+			},
+		},
+	}
+
+	ignored := p.IgnoredPackages()
+
+	cases := []struct {
+		importPath string
+		want       bool
+	}{
+		{"example.com/foo", false},
+		{"example.com/foo/bar", true},
+		{"example.com/foo/bar/baz", true},
+		{"example.com/foo/keepme", false},
+		{"example.com/foo/keepme/sub", true},
+		{"example.com/other", false},
+		{"example.com/other/internal/testdata", true},
+		{"internal/testdata", true},
+	}
+
+	for _, c := range cases {
+		if got := ignored(c.importPath); got != c.want {
+			t.Errorf("IgnoredPackages()(%q) = %v, want %v", c.importPath, got, c.want)
+		}
+	}
+}
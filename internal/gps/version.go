@@ -0,0 +1,111 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gps
+
+import "fmt"
+
+// ProjectRoot is the topmost import path in a tree of other import paths -
+// the root of the tree. In gps' current design, ProjectRoots have to
+// correspond to a repository root (except in special cases like stdlib).
+type ProjectRoot string
+
+// VersionType indicates the type of version - branch, revision, version, or
+// semver - being represented.
+type VersionType uint8
+
+const (
+	IsRevision VersionType = iota
+	IsBranch
+	IsVersion
+	IsSemver
+)
+
+// Version represents one of the different types of versions used by gps.
+type Version interface {
+	Type() VersionType
+	String() string
+}
+
+// UnpairedVersion represents a normal Version, with a method for splicing in
+// an underlying Revision.
+type UnpairedVersion interface {
+	Version
+	Pair(r Revision) PairedVersion
+	Unpaired() UnpairedVersion
+}
+
+// PairedVersion represents a normal Version, but paired with the underlying
+// Revision it corresponds to.
+type PairedVersion interface {
+	Version
+	Revision() Revision
+	Unpair() UnpairedVersion
+}
+
+// Revision represents a VCS revision, such as a git commit hash.
+type Revision string
+
+func (r Revision) String() string    { return string(r) }
+func (r Revision) Type() VersionType { return IsRevision }
+
+type branchVersion struct {
+	name string
+}
+
+func (v branchVersion) String() string    { return v.name }
+func (v branchVersion) Type() VersionType { return IsBranch }
+func (v branchVersion) Pair(r Revision) PairedVersion {
+	return versionPair{v: v, r: r}
+}
+func (v branchVersion) Unpaired() UnpairedVersion { return v }
+
+type plainVersion struct {
+	name string
+}
+
+func (v plainVersion) String() string    { return v.name }
+func (v plainVersion) Type() VersionType { return IsVersion }
+func (v plainVersion) Pair(r Revision) PairedVersion {
+	return versionPair{v: v, r: r}
+}
+func (v plainVersion) Unpaired() UnpairedVersion { return v }
+
+type versionPair struct {
+	v UnpairedVersion
+	r Revision
+}
+
+func (v versionPair) String() string          { return v.v.String() }
+func (v versionPair) Type() VersionType       { return v.v.Type() }
+func (v versionPair) Revision() Revision      { return v.r }
+func (v versionPair) Unpair() UnpairedVersion { return v.v }
+
+// NewVersion produces a Version from a string, interpreting it as a plain
+// (non-semver, non-branch) tag version.
+func NewVersion(body string) UnpairedVersion {
+	return plainVersion{name: body}
+}
+
+// NewBranch creates a new Version to represent a floating version (in
+// general, a branch).
+func NewBranch(body string) UnpairedVersion {
+	return branchVersion{name: body}
+}
+
+// ProjectConstraint pairs a ProjectRoot with a Constraint, and is used to
+// specify dependency constraints in a manifest or lock.
+type ProjectConstraint struct {
+	Ident      ProjectRoot
+	Constraint Constraint
+	// Source, when non-empty, overrides where the project is fetched from -
+	// used for replace-style directives (e.g. a local path or fork).
+	Source string
+}
+
+// Constraint represents a constraint on the acceptable versions of a
+// dependency.
+type Constraint interface {
+	fmt.Stringer
+}
@@ -0,0 +1,86 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lockedfile provides a cross-process, cross-platform advisory lock
+// on a file path. It exists so that concurrent dep invocations - a CI job
+// and an editor plugin, or two developers sharing a vendor directory over a
+// network filesystem - don't race on reads and writes of Gopkg.toml and
+// Gopkg.lock.
+package lockedfile
+
+import (
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Mutex is an advisory file lock keyed by Path. Lock and RLock acquire an
+// OS-level lock on the file in addition to an in-process sync.Mutex, and the
+// func they return releases both, so both other processes and the race
+// detector see the critical section.
+type Mutex struct {
+	Path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Lock acquires an exclusive lock on m.Path, creating the file if it does
+// not already exist, and blocks until the lock is held.
+func (m *Mutex) Lock() (func(), error) {
+	m.mu.Lock()
+
+	f, err := os.OpenFile(m.Path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, errors.Wrapf(err, "opening %s for locking", m.Path)
+	}
+
+	if err := lockFile(f, true); err != nil {
+		f.Close()
+		m.mu.Unlock()
+		return nil, errors.Wrapf(err, "locking %s", m.Path)
+	}
+	m.file = f
+
+	return m.unlock, nil
+}
+
+// RLock acquires a shared (read) lock on m.Path, blocking until it is held.
+// Shared locks taken through separate Mutex values (or by separate
+// processes) may be held concurrently by any number of readers, but exclude
+// any writer holding Lock. RLock still takes m's in-process sync.Mutex
+// exclusively, so concurrent RLock calls on the same Mutex run one at a time.
+// Unlike Lock, RLock never creates m.Path: if the file doesn't exist yet
+// there is nothing to lock against, and RLock returns an *os.PathError
+// satisfying os.IsNotExist instead.
+func (m *Mutex) RLock() (func(), error) {
+	m.mu.Lock()
+
+	f, err := os.OpenFile(m.Path, os.O_RDONLY, 0644)
+	if err != nil {
+		m.mu.Unlock()
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "opening %s for locking", m.Path)
+	}
+
+	if err := lockFile(f, false); err != nil {
+		f.Close()
+		m.mu.Unlock()
+		return nil, errors.Wrapf(err, "locking %s", m.Path)
+	}
+	m.file = f
+
+	return m.unlock, nil
+}
+
+func (m *Mutex) unlock() {
+	unlockFile(m.file)
+	m.file.Close()
+	m.file = nil
+	m.mu.Unlock()
+}
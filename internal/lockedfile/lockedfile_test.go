@@ -0,0 +1,113 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lockedfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMutexConcurrentReaders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lockedfile-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "Gopkg.lock")
+	if err := ioutil.WriteFile(path, []byte("memo = \"x\""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := &Mutex{Path: path}
+			unlock, err := m.RLock()
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer unlock()
+			time.Sleep(10 * time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent RLock failed: %v", err)
+	}
+}
+
+func TestMutexExcludesWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lockedfile-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "Gopkg.lock")
+
+	writer := &Mutex{Path: path}
+	unlock, err := writer.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		reader := &Mutex{Path: path}
+		runlock, err := reader.RLock()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		runlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("reader acquired the lock while the writer still held it")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	<-done
+}
+
+func TestMutexStaleLockAfterClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lockedfile-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "Gopkg.lock")
+
+	// Simulate a crashed holder: the OS releases the flock when the file
+	// descriptor is closed, even without calling our unlock func.
+	first := &Mutex{Path: path}
+	_, err = first.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.file.Close()
+
+	second := &Mutex{Path: path}
+	unlock, err := second.Lock()
+	if err != nil {
+		t.Fatalf("expected stale lock to be reclaimable, got: %v", err)
+	}
+	unlock()
+}
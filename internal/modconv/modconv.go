@@ -0,0 +1,194 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package modconv converts a Go modules manifest (go.mod, plus a go.sum if
+// present) into the in-memory representation dep uses for Gopkg.toml and
+// Gopkg.lock, so that a project pinned with modules can be loaded by dep
+// without first running `dep init`.
+package modconv
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/dep/internal/gps"
+	"github.com/pkg/errors"
+)
+
+// GoModName is the file name Go modules uses for its manifest.
+const GoModName = "go.mod"
+
+// GoSumName is the file name Go modules uses for its lock file.
+const GoSumName = "go.sum"
+
+// Require is a single require directive parsed out of a go.mod file.
+type Require struct {
+	Path    string
+	Version string // tagged version ("v1.2.3") or pseudo-version ("v0.0.0-date-commit")
+}
+
+// Replace is a single replace directive parsed out of a go.mod file.
+type Replace struct {
+	Old Require
+	New Require // New.Path is a filesystem path when the replacement is local
+}
+
+// Exclude is a single exclude directive parsed out of a go.mod file.
+type Exclude struct {
+	Path    string
+	Version string
+}
+
+// GoMod holds the directives of a parsed go.mod file.
+type GoMod struct {
+	Module   string
+	Requires []Require
+	Replaces []Replace
+	Excludes []Exclude
+}
+
+// ParseGoMod parses the go.mod file at root into a GoMod.
+func ParseGoMod(root string) (*GoMod, error) {
+	f, err := os.Open(filepath.Join(root, GoModName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gm := &GoMod{}
+	sc := bufio.NewScanner(f)
+	var block string
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		if block != "" {
+			if line == ")" {
+				block = ""
+				continue
+			}
+			if err := parseDirective(gm, block, line); err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", GoModName)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "module "):
+			gm.Module = strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		case line == "require (", line == "replace (", line == "exclude (":
+			block = strings.Fields(line)[0]
+		case strings.HasPrefix(line, "require "), strings.HasPrefix(line, "replace "), strings.HasPrefix(line, "exclude "):
+			fields := strings.SplitN(line, " ", 2)
+			if err := parseDirective(gm, fields[0], fields[1]); err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", GoModName)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", GoModName)
+	}
+
+	return gm, nil
+}
+
+func parseDirective(gm *GoMod, kind, line string) error {
+	switch kind {
+	case "require":
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return errors.Errorf("malformed require directive: %q", line)
+		}
+		gm.Requires = append(gm.Requires, Require{Path: fields[0], Version: fields[1]})
+	case "exclude":
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return errors.Errorf("malformed exclude directive: %q", line)
+		}
+		gm.Excludes = append(gm.Excludes, Exclude{Path: fields[0], Version: fields[1]})
+	case "replace":
+		parts := strings.SplitN(line, "=>", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("malformed replace directive: %q", line)
+		}
+		oldFields := strings.Fields(parts[0])
+		newFields := strings.Fields(strings.TrimSpace(parts[1]))
+		if len(oldFields) == 0 || len(newFields) == 0 {
+			return errors.Errorf("malformed replace directive: %q", line)
+		}
+		r := Replace{Old: Require{Path: oldFields[0]}}
+		if len(oldFields) > 1 {
+			r.Old.Version = oldFields[1]
+		}
+		r.New.Path = newFields[0]
+		if len(newFields) > 1 {
+			r.New.Version = newFields[1]
+		}
+		gm.Replaces = append(gm.Replaces, r)
+	}
+	return nil
+}
+
+// Constraint converts a go.mod Require into a gps.ProjectConstraint,
+// translating a tagged version into a gps.Version and a pseudo-version
+// (vX.Y.Z-yyyymmddhhmmss-commit) into the gps.Revision of its trailing
+// commit hash.
+func Constraint(r Require) gps.ProjectConstraint {
+	pc := gps.ProjectConstraint{Ident: gps.ProjectRoot(r.Path)}
+	if rev := pseudoVersionRevision(r.Version); rev != "" {
+		pc.Constraint = gps.Revision(rev)
+	} else {
+		pc.Constraint = gps.NewVersion(r.Version)
+	}
+	return pc
+}
+
+// pseudoVersionRevision returns the commit hash embedded in a Go modules
+// pseudo-version (e.g. "v0.0.0-20180101000000-abcdef123456"), or "" if v is
+// not a pseudo-version.
+func pseudoVersionRevision(v string) string {
+	parts := strings.Split(v, "-")
+	if len(parts) < 3 {
+		return ""
+	}
+	commit := parts[len(parts)-1]
+	if len(commit) != 12 && len(commit) != 40 {
+		return ""
+	}
+	for _, r := range commit {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return ""
+		}
+	}
+	return commit
+}
+
+// ParseGoSum parses the go.sum file at root, returning the pinned
+// module@version -> h1 hash pairs it records. dep uses these only to fill in
+// SolveMeta provenance, not to re-verify the hash scheme itself.
+func ParseGoSum(root string) (map[string]string, error) {
+	f, err := os.Open(filepath.Join(root, GoSumName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		sums[fields[0]+"@"+fields[1]] = fields[2]
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", GoSumName)
+	}
+	return sums, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modconv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/dep/internal/gps"
+	"github.com/golang/dep/internal/test"
+)
+
+func TestParseGoMod(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	h.TempFile(GoModName, `module example.com/foo
+
+require example.com/bar v1.2.3
+require (
+	example.com/baz v0.0.0-20180101000000-abcdef123456
+	example.com/qux v2.0.0
+)
+
+exclude example.com/qux v1.0.0
+
+replace example.com/bar => example.com/bar-fork v1.2.4
+replace example.com/baz => ../baz
+`)
+
+	gm, err := ParseGoMod(h.Path("."))
+	if err != nil {
+		t.Fatalf("ParseGoMod failed: %+v", err)
+	}
+
+	if gm.Module != "example.com/foo" {
+		t.Errorf("got module %q, want example.com/foo", gm.Module)
+	}
+
+	wantRequires := []Require{
+		{Path: "example.com/bar", Version: "v1.2.3"},
+		{Path: "example.com/baz", Version: "v0.0.0-20180101000000-abcdef123456"},
+		{Path: "example.com/qux", Version: "v2.0.0"},
+	}
+	if !reflect.DeepEqual(gm.Requires, wantRequires) {
+		t.Errorf("got requires %+v, want %+v", gm.Requires, wantRequires)
+	}
+
+	wantExcludes := []Exclude{{Path: "example.com/qux", Version: "v1.0.0"}}
+	if !reflect.DeepEqual(gm.Excludes, wantExcludes) {
+		t.Errorf("got excludes %+v, want %+v", gm.Excludes, wantExcludes)
+	}
+
+	wantReplaces := []Replace{
+		{Old: Require{Path: "example.com/bar"}, New: Require{Path: "example.com/bar-fork", Version: "v1.2.4"}},
+		{Old: Require{Path: "example.com/baz"}, New: Require{Path: "../baz"}},
+	}
+	if !reflect.DeepEqual(gm.Replaces, wantReplaces) {
+		t.Errorf("got replaces %+v, want %+v", gm.Replaces, wantReplaces)
+	}
+}
+
+func TestParseGoModMissing(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	if _, err := ParseGoMod(h.Path(".")); err == nil {
+		t.Fatal("expected an error for a missing go.mod")
+	}
+}
+
+func TestParseGoSum(t *testing.T) {
+	h := test.NewHelper(t)
+	defer h.Cleanup()
+
+	h.TempFile(GoSumName, `example.com/bar v1.2.3 h1:abc=
+example.com/bar v1.2.3/go.mod h1:def=
+`)
+
+	sums, err := ParseGoSum(h.Path("."))
+	if err != nil {
+		t.Fatalf("ParseGoSum failed: %+v", err)
+	}
+
+	want := map[string]string{
+		"example.com/bar@v1.2.3":        "h1:abc=",
+		"example.com/bar@v1.2.3/go.mod": "h1:def=",
+	}
+	if !reflect.DeepEqual(sums, want) {
+		t.Errorf("got sums %+v, want %+v", sums, want)
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	cases := []struct {
+		name string
+		req  Require
+		want gps.Constraint
+	}{
+		{
+			name: "tagged version",
+			req:  Require{Path: "example.com/bar", Version: "v1.2.3"},
+			want: gps.NewVersion("v1.2.3"),
+		},
+		{
+			name: "pseudo-version with short commit",
+			req:  Require{Path: "example.com/baz", Version: "v0.0.0-20180101000000-abcdef123456"},
+			want: gps.Revision("abcdef123456"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pc := Constraint(c.req)
+			if pc.Ident != gps.ProjectRoot(c.req.Path) {
+				t.Errorf("got ident %q, want %q", pc.Ident, c.req.Path)
+			}
+			if !reflect.DeepEqual(pc.Constraint, c.want) {
+				t.Errorf("got constraint %#v, want %#v", pc.Constraint, c.want)
+			}
+		})
+	}
+}
+
+func TestPseudoVersionRevision(t *testing.T) {
+	cases := []struct {
+		version string
+		want    string
+	}{
+		{"v0.0.0-20180101000000-abcdef123456", "abcdef123456"},
+		{"v1.2.3-0.20180101000000-0123456789abcdef0123456789abcdef01234567", "0123456789abcdef0123456789abcdef01234567"},
+		{"v1.2.3", ""},
+		{"v0.0.0-notenoughparts", ""},
+		{"v0.0.0-20180101000000-nothex12345z", ""},
+	}
+
+	for _, c := range cases {
+		if got := pseudoVersionRevision(c.version); got != c.want {
+			t.Errorf("pseudoVersionRevision(%q) = %q, want %q", c.version, got, c.want)
+		}
+	}
+}
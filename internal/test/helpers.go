@@ -0,0 +1,148 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package test holds utilities shared by dep's test suites: a scratch-dir
+// helper modeled on cmd/go's testgo harness, plus guards for tests that
+// need network access or a git binary.
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// Helper tracks a temporary directory and environment changes made on
+// behalf of a single test, undoing them on Cleanup.
+type Helper struct {
+	t       *testing.T
+	temp    string
+	env     []string
+	wd      string
+	haveWd  bool
+	cleanup []func()
+}
+
+// NewHelper creates a Helper rooted at a fresh temporary directory.
+func NewHelper(t *testing.T) *Helper {
+	t.Helper()
+	temp, err := ioutil.TempDir("", "dep-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Helper{t: t, temp: temp}
+}
+
+// Cleanup restores the environment and removes the temporary directory.
+func (h *Helper) Cleanup() {
+	for i := len(h.cleanup) - 1; i >= 0; i-- {
+		h.cleanup[i]()
+	}
+	if h.haveWd {
+		os.Chdir(h.wd)
+	}
+	os.RemoveAll(h.temp)
+}
+
+// Path returns the absolute path of rel within the helper's temp directory.
+func (h *Helper) Path(rel string) string {
+	if rel == "." {
+		return h.temp
+	}
+	return filepath.Join(h.temp, rel)
+}
+
+// TempDir creates a directory rel within the helper's temp directory.
+func (h *Helper) TempDir(rel string) {
+	h.t.Helper()
+	if err := os.MkdirAll(h.Path(rel), 0755); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// TempFile writes contents to rel within the helper's temp directory,
+// creating any parent directories as needed.
+func (h *Helper) TempFile(rel, contents string) {
+	h.t.Helper()
+	path := h.Path(rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		h.t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// Setenv sets an environment variable for the duration of the test.
+func (h *Helper) Setenv(name, val string) {
+	old, had := os.LookupEnv(name)
+	os.Setenv(name, val)
+	h.cleanup = append(h.cleanup, func() {
+		if had {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+// Cd changes the working directory for the duration of the test.
+func (h *Helper) Cd(dir string) {
+	h.t.Helper()
+	if !h.haveWd {
+		wd, err := os.Getwd()
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		h.wd, h.haveWd = wd, true
+	}
+	if err := os.Chdir(dir); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// Must fails the test immediately if err is non-nil.
+func (h *Helper) Must(err error) {
+	if err != nil {
+		h.t.Helper()
+		h.t.Fatal(err)
+	}
+}
+
+// RunGo runs the go tool with the given arguments.
+func (h *Helper) RunGo(args ...string) {
+	h.t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Env = os.Environ()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		h.t.Fatalf("go %v: %v\n%s", args, err, out)
+	}
+}
+
+// RunGit runs git in dir with the given arguments.
+func (h *Helper) RunGit(dir string, args ...string) {
+	h.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		h.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// NeedsExternalNetwork skips the test unless network-dependent tests have
+// been explicitly enabled.
+func NeedsExternalNetwork(t *testing.T) {
+	if os.Getenv("DEPTESTS_NETWORK") == "" {
+		t.Skip("skipping test that requires network access; set DEPTESTS_NETWORK=1 to enable")
+	}
+}
+
+// NeedsGit skips the test if a git binary isn't available on PATH.
+func NeedsGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("skipping test that requires git")
+	}
+}
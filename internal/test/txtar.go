@@ -0,0 +1,108 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractTxtar parses the single-file txtar archive at archivePath and
+// extracts its files into a fresh GOPATH rooted at h.Path("gopath"). The
+// archive's leading comment (the text before the first "-- path --" marker)
+// names a working directory, as a path relative to that GOPATH, on its own
+// line; file names are relative to the working directory. ExtractTxtar
+// returns the absolute path of the working directory. For example:
+//
+//	src/test1
+//	-- Gopkg.toml --
+//	[[constraint]]
+//	-- Gopkg.lock --
+//	memo = "abc123"
+//	-- sub/.gitkeep --
+//
+// extracts Gopkg.toml and Gopkg.lock under gopath/src/test1, creates
+// gopath/src/test1/sub, and returns gopath/src/test1.
+func (h *Helper) ExtractTxtar(archivePath string) string {
+	h.t.Helper()
+
+	data, err := ioutil.ReadFile(archivePath)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	comment, files := parseTxtar(data)
+
+	wd := strings.TrimSpace(comment)
+	if wd == "" {
+		h.t.Fatalf("%s: txtar archive is missing its working-directory comment", archivePath)
+	}
+
+	root := h.Path("gopath")
+	for name, contents := range files {
+		path := filepath.Join(root, filepath.FromSlash(wd), name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			h.t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			h.t.Fatal(err)
+		}
+	}
+
+	return filepath.Join(root, filepath.FromSlash(wd))
+}
+
+// parseTxtar splits a txtar archive into its leading comment and a map of
+// file name to contents. File markers are lines of the exact form
+// "-- name --"; everything up to the first marker is the comment.
+func parseTxtar(data []byte) (comment string, files map[string]string) {
+	files = make(map[string]string)
+
+	lines := strings.Split(string(data), "\n")
+	var name string
+	var body []string
+	var inFile bool
+
+	flush := func() {
+		if inFile {
+			files[name] = strings.Join(body, "\n")
+		}
+	}
+
+	var commentLines []string
+	for _, line := range lines {
+		if n, ok := fileMarkerName(line); ok {
+			flush()
+			name = n
+			body = nil
+			inFile = true
+			continue
+		}
+		if !inFile {
+			commentLines = append(commentLines, line)
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return strings.Join(commentLines, "\n"), files
+}
+
+// fileMarkerName reports whether line is a "-- name --" file marker, and if
+// so, returns name.
+func fileMarkerName(line string) (string, bool) {
+	const prefix, suffix = "-- ", " --"
+	if !strings.HasPrefix(line, prefix) || !strings.HasSuffix(line, suffix) {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(line, prefix), suffix)
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
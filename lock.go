@@ -0,0 +1,80 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import (
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+
+	"github.com/BurntSushi/toml"
+	"github.com/golang/dep/internal/gps"
+	"github.com/pkg/errors"
+)
+
+// LockName is the lock file name used by dep.
+const LockName = "Gopkg.lock"
+
+// LockedProject is a single project entry from a Lock file.
+type LockedProject struct {
+	Ident   gps.ProjectRoot
+	Version gps.Version
+}
+
+// Lock holds lock file data.
+type Lock struct {
+	SolveMeta SolveMeta
+	P         []LockedProject
+}
+
+// SolveMeta holds metadata about the solve that produced a Lock.
+type SolveMeta struct {
+	InputsDigest []byte
+}
+
+func readLock(r io.Reader) (*Lock, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read lock")
+	}
+
+	var raw rawLock
+	if err := toml.Unmarshal(buf, &raw); err != nil {
+		return nil, errors.Wrap(err, "failed to parse lock")
+	}
+
+	l := &Lock{}
+	if raw.Memo != "" {
+		digest, err := hex.DecodeString(raw.Memo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse lock: memo is not valid hex")
+		}
+		l.SolveMeta.InputsDigest = digest
+	}
+	for _, p := range raw.Projects {
+		lp := LockedProject{Ident: gps.ProjectRoot(p.Name)}
+		if p.Branch != "" {
+			lp.Version = gps.NewBranch(p.Branch).Pair(gps.Revision(p.Revision))
+		} else if p.Version != "" {
+			lp.Version = gps.NewVersion(p.Version).Pair(gps.Revision(p.Revision))
+		} else {
+			lp.Version = gps.Revision(p.Revision)
+		}
+		l.P = append(l.P, lp)
+	}
+	return l, nil
+}
+
+type rawLock struct {
+	Memo     string       `toml:"memo"`
+	Projects []rawProject `toml:"projects"`
+}
+
+type rawProject struct {
+	Name     string `toml:"name"`
+	Branch   string `toml:"branch"`
+	Version  string `toml:"version"`
+	Revision string `toml:"revision"`
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadLockMemo(t *testing.T) {
+	r := strings.NewReader(`memo = "cdafe8641b28cd16fe025df278b0a49b9416859345d8b6ba0ace0272b74925ee"`)
+
+	l, err := readLock(r)
+	if err != nil {
+		t.Fatalf("readLock failed: %+v", err)
+	}
+
+	want := []byte{0xcd, 0xaf, 0xe8, 0x64, 0x1b, 0x28, 0xcd, 0x16, 0xfe, 0x02, 0x5d, 0xf2, 0x78, 0xb0, 0xa4, 0x9b, 0x94, 0x16, 0x85, 0x93, 0x45, 0xd8, 0xb6, 0xba, 0x0a, 0xce, 0x02, 0x72, 0xb7, 0x49, 0x25, 0xee}
+	if !bytes.Equal(l.SolveMeta.InputsDigest, want) {
+		t.Errorf("got InputsDigest %x, want %x", l.SolveMeta.InputsDigest, want)
+	}
+}
+
+func TestReadLockMemoInvalidHex(t *testing.T) {
+	r := strings.NewReader(`memo = "not-hex"`)
+
+	if _, err := readLock(r); err == nil {
+		t.Fatal("expected an error for a non-hex memo")
+	}
+}
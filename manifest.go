@@ -0,0 +1,81 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/BurntSushi/toml"
+	"github.com/golang/dep/internal/gps"
+	"github.com/pkg/errors"
+)
+
+// ManifestName is the manifest file name used by dep.
+const ManifestName = "Gopkg.toml"
+
+// Manifest holds manifest file data.
+type Manifest struct {
+	Constraints []gps.ProjectConstraint
+	Ovr         []gps.ProjectConstraint
+	Ignored     []string
+	Required    []string
+	// Module is the import path declared by an optional `module = "..."`
+	// line, for projects that live outside a GOPATH. See Ctx.ModuleRoot.
+	Module string
+}
+
+// readManifest parses the manifest read from r into a *Manifest.
+func readManifest(r io.Reader) (*Manifest, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest")
+	}
+
+	var raw rawManifest
+	if err := toml.Unmarshal(buf, &raw); err != nil {
+		return nil, errors.Wrap(err, "failed to parse manifest")
+	}
+
+	m := &Manifest{
+		Ignored:  raw.Ignored,
+		Required: raw.Required,
+		Module:   raw.Module,
+	}
+	for _, c := range raw.Constraints {
+		m.Constraints = append(m.Constraints, c.toProjectConstraint())
+	}
+	for _, c := range raw.Overrides {
+		m.Ovr = append(m.Ovr, c.toProjectConstraint())
+	}
+	return m, nil
+}
+
+// rawManifest is the TOML serialization of Manifest.
+type rawManifest struct {
+	Constraints []rawConstraint `toml:"constraint"`
+	Overrides   []rawConstraint `toml:"override"`
+	Ignored     []string        `toml:"ignored"`
+	Required    []string        `toml:"required"`
+	Module      string          `toml:"module"`
+}
+
+type rawConstraint struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	Branch  string `toml:"branch"`
+	Source  string `toml:"source"`
+}
+
+func (c rawConstraint) toProjectConstraint() gps.ProjectConstraint {
+	pc := gps.ProjectConstraint{Ident: gps.ProjectRoot(c.Name), Source: c.Source}
+	switch {
+	case c.Branch != "":
+		pc.Constraint = gps.NewBranch(c.Branch)
+	case c.Version != "":
+		pc.Constraint = gps.NewVersion(c.Version)
+	}
+	return pc
+}
@@ -0,0 +1,22 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dep
+
+import "github.com/golang/dep/internal/gps"
+
+// Project depicts a Go project, which is a workspace rooted at a particular
+// import path, that has metadata files describing both direct and transitive
+// dependency requirements.
+type Project struct {
+	// AbsRoot is the absolute path to the root directory of the project.
+	AbsRoot string
+	// ResolvedAbsRoot is the absolute path to the project's root directory,
+	// after any symlinks have been resolved.
+	ResolvedAbsRoot string
+	// ImportRoot is the import path of the project's root directory.
+	ImportRoot gps.ProjectRoot
+	Manifest   *Manifest
+	Lock       *Lock // Optional
+}